@@ -21,10 +21,16 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
 	"flag"
 	"fmt"
 	"io"
 	"log"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -32,14 +38,27 @@ import (
 	"time"
 )
 
-var ExecPath = "C:\\Program Files (x86)\\Guild Wars 2\\Gw2-64.exe"
-var ProfileDir = filepath.Join(os.Getenv("APPDATA"), "Guild Wars 2")
-var LoadedProfile = filepath.Join(ProfileDir, "Local.dat")
+// gzipMagic is the leading byte sequence of a gzip stream (RFC 1952 2.3.1)
+var gzipMagic = []byte{0x1F, 0x8B, 0x08}
+
+// ExecPath, ProfileDir and LoadedProfile are resolved at startup by
+// ResolvePaths; see paths.go
+var ExecPath string
+var ProfileDir string
+var LoadedProfile string
 
 func main() {
 	// Profile options
-	name := flag.String("name", "Local", "Profile name to load")
+	nameFlag := flag.String("name", "Local", "Profile name to load, or \"?\" to pick one interactively")
+	set := flag.String("set", "", "Name of a profile set (from the config file) to launch concurrently")
 	n := flag.Int("n", 2, "Number of profile backups to keep")
+	insecure := flag.Bool("insecure", false, "Skip TLS verification when fetching a profile URL")
+	fetchTimeout := flag.Duration("fetch-timeout", 10*time.Second, "Timeout for fetching a profile URL")
+	compress := flag.Bool("compress", false, "Write profile backups as gzip regardless of input format")
+
+	// Path discovery options
+	config := flag.String("config", "", "Path to gw2util config.toml (default: "+ConfigPath()+")")
+	execOverride := flag.String("exec", "", "Override the discovered Guild Wars 2 executable path")
 
 	// Guild Wars 2 launch options
 	autologin := flag.Bool("autologin", true, "Log in automatically")
@@ -50,6 +69,12 @@ func main() {
 
 	// Parse flags and initialize launch options
 	flag.Parse()
+	cfg := LoadConfig(*config)
+	paths := ResolvePaths(cfg, *execOverride)
+	ExecPath = paths.Exec
+	ProfileDir = paths.ProfileDir
+	LoadedProfile = filepath.Join(ProfileDir, "Local.dat")
+
 	options := flag.Args()
 	if *autologin {
 		options = append(options, "-autologin")
@@ -67,7 +92,58 @@ func main() {
 			"-nopatchui",
 		}, options...)
 	}
-	profile := Profile{Name: *name, Preserve: *n, Options: options}
+
+	// A named profile set launches all of its members concurrently and
+	// takes over completely; it doesn't fall through to single-profile
+	// loading below.
+	if *set != "" {
+		members, ok := cfg.Sets[*set]
+		if !ok || len(members) == 0 {
+			Exit(fmt.Errorf("no profile set named %q in config", *set))
+		}
+		profileSet := ProfileSet{
+			Name:         *set,
+			Members:      members,
+			Preserve:     *n,
+			Options:      options,
+			Insecure:     *insecure,
+			FetchTimeout: *fetchTimeout,
+			Compress:     *compress,
+		}
+		if failures := profileSet.Launch(); len(failures) > 0 {
+			ExitSet(failures)
+		}
+		return
+	}
+
+	// Fall through to an interactive picker when no profile was named
+	// explicitly (or "?" was given) and stdin looks like a terminal;
+	// otherwise keep the plain CLI behavior of launching "Local" untouched
+	name := *nameFlag
+	nameGiven := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "name" {
+			nameGiven = true
+		}
+	})
+	if (!nameGiven || name == "?") && isInteractive() {
+		picked, err := PickProfile()
+		if err != nil {
+			Exit(err)
+		}
+		name = picked
+	} else if name == "?" {
+		name = "Local"
+	}
+
+	profile := Profile{
+		Name:         name,
+		Preserve:     *n,
+		Options:      options,
+		Insecure:     *insecure,
+		FetchTimeout: *fetchTimeout,
+		Compress:     *compress,
+	}
 
 	// Profile name "local" skips loading/unloading
 	//   (default behavior acts as a simple link to the GW2 executable)
@@ -93,46 +169,249 @@ func LaunchGW2(options []string) error {
 }
 
 type Profile struct {
-	Name     string
-	Options  []string
-	Preserve int
-	path     string
+	Name         string
+	Options      []string
+	Preserve     int
+	Insecure     bool
+	FetchTimeout time.Duration
+	Compress     bool
+
+	// ProfileDir and LoadedProfile override the package-level ProfileDir and
+	// LoadedProfile globals when set, which a ProfileSet uses to give each
+	// of its members an isolated Local.dat for concurrent launches.
+	ProfileDir    string
+	LoadedProfile string
+
+	path       string
+	compressed bool
+}
+
+// IsRemote returns true IFF the profile name is an http(s) URL
+func (this *Profile) IsRemote() bool {
+	return strings.HasPrefix(this.Name, "http://") || strings.HasPrefix(this.Name, "https://")
+}
+
+func (this *Profile) profileDir() string {
+	if this.ProfileDir != "" {
+		return this.ProfileDir
+	}
+	return ProfileDir
+}
+
+func (this *Profile) loadedProfile() string {
+	if this.LoadedProfile != "" {
+		return this.LoadedProfile
+	}
+	return LoadedProfile
 }
 
 func (this *Profile) Path() string {
 	if this.path != "" {
 		return this.path
 	}
-	return filepath.Join(ProfileDir, this.Name+".dat")
+	if this.IsRemote() {
+		return CachePath(this.profileDir(), this.Name)
+	}
+	return filepath.Join(this.profileDir(), this.Name+".dat")
 }
 
 // LoadFile backs up original state and loads the saved profile
 func (this *Profile) LoadFile() error {
+	// Make sure the loaded-profile location exists; for an isolated
+	// ProfileSet member this is a fresh per-instance directory
+	if err := os.MkdirAll(filepath.Dir(this.loadedProfile()), 0755); err != nil {
+		return err
+	}
+
+	// If the profile is remote, fetch it to the local cache first
+	if this.IsRemote() {
+		if err := this.FetchRemote(); err != nil {
+			return err
+		}
+	}
+
 	// If a profile is already loaded, rename it as a backup
-	if err := SimpleBackup(LoadedProfile); err != nil {
+	if err := SimpleBackup(this.loadedProfile()); err != nil {
 		return err
 	}
 
-	// Copy profile data file to loaded position
+	// Find the newest profile file that still matches its checksum, falling
+	// back through older backups if the primary copy is corrupt
+	path, err := this.VerifiedPath()
+	if err != nil {
+		return err
+	}
+
+	// Copy profile data file to loaded position, transparently decompressing
+	// a gzip-compressed archive detected by its magic bytes
 	log.Printf("loading profile for %s\n", this.Name)
-	return SimpleCopy(this.Path(), LoadedProfile)
+	compressed, err := IsGzip(path)
+	if err != nil {
+		return err
+	}
+	this.compressed = compressed
+	if compressed {
+		return CopyDecompressed(path, this.loadedProfile())
+	}
+	return SimpleCopy(path, this.loadedProfile())
+}
+
+// VerifiedPath returns the newest profile file for this profile whose
+// contents match its persisted checksum, trying this.Path() first and then
+// each older backup in turn. A candidate with no checksum on record is
+// trusted, since profiles saved before checksum verification was added have
+// none.
+func (this *Profile) VerifiedPath() (string, error) {
+	candidates := []string{this.Path()}
+	for i := 0; i < this.Preserve; i++ {
+		candidates = append(candidates, this.getBackup(i))
+	}
+
+	for _, candidate := range candidates {
+		if !FileExists(candidate) {
+			continue
+		}
+		ok, err := VerifyHash(candidate)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return candidate, nil
+		}
+		log.Printf("profile backup failed checksum verification, trying next: %s\n", candidate)
+	}
+	return "", fmt.Errorf("no valid profile found for %s", this.Name)
+}
+
+// FetchRemote downloads the profile's URL into the local cache, honoring
+// If-Modified-Since/ETag so an unchanged profile is skipped on later runs.
+// If the network is unreachable, the last good cached copy is kept and used.
+func (this *Profile) FetchRemote() error {
+	cache := this.Path()
+	meta := cache + ".meta"
+
+	if err := os.MkdirAll(filepath.Dir(cache), 0755); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("GET", this.Name, nil)
+	if err != nil {
+		return err
+	}
+	if etag, modified, ok := readCacheMeta(meta); ok {
+		req.Header.Set("If-None-Match", etag)
+		req.Header.Set("If-Modified-Since", modified)
+	}
+
+	client := &http.Client{Timeout: this.FetchTimeout}
+	if this.Insecure {
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+
+	log.Printf("fetching profile from %s\n", this.Name)
+	resp, err := client.Do(req)
+	if err != nil {
+		if FileExists(cache) {
+			log.Println("profile host unreachable, using cached copy:", err)
+			return nil
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		log.Println("cached profile is up to date")
+		return nil
+	case http.StatusOK:
+		if err := SimpleCopyReader(resp.Body, cache); err != nil {
+			return err
+		}
+		// Any sidecar checksum on record for the cache was computed over
+		// the old content; it no longer matches what was just fetched, so
+		// drop it rather than have VerifiedPath reject the update as
+		// corrupt.
+		if err := os.Remove(cache + ".sha256"); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return writeCacheMeta(meta, resp.Header.Get("ETag"), resp.Header.Get("Last-Modified"))
+	default:
+		if FileExists(cache) {
+			log.Println("unexpected response fetching profile, using cached copy:", resp.Status)
+			return nil
+		}
+		return fmt.Errorf("fetching profile: %s", resp.Status)
+	}
+}
+
+// CachePath returns the local cache location for a remote profile URL under
+// the given profile directory
+func CachePath(dir, url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(dir, "profiles", hex.EncodeToString(sum[:])+".dat")
+}
+
+func readCacheMeta(file string) (etag, modified string, ok bool) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", "", false
+	}
+	lines := strings.SplitN(string(data), "\n", 2)
+	if len(lines) != 2 {
+		return "", "", false
+	}
+	return lines[0], lines[1], true
+}
+
+func writeCacheMeta(file, etag, modified string) error {
+	if etag == "" && modified == "" {
+		return nil
+	}
+	return os.WriteFile(file, []byte(etag+"\n"+modified), 0644)
 }
 
-// UnloadFile saves changes to the profile and restores original state
+// UnloadFile saves changes to the profile and restores original state. A
+// remote profile's cache is a read-only mirror of its URL, kept in sync by
+// FetchRemote alone, so the played session is discarded instead of being
+// written back over it; doing otherwise would mutate the cache out from
+// under its ETag/Last-Modified bookkeeping and the remote update would never
+// be seen again.
 func (this *Profile) UnloadFile() error {
+	if this.IsRemote() {
+		log.Printf("unloading profile for %s\n", this.Name)
+		return SimpleRestore(this.loadedProfile())
+	}
+
 	// Rotate backups of profile data
 	if err := this.RollBackups(); err != nil {
 		return err
 	}
 
-	// Copy profile data file from loaded position
+	// Copy profile data file from loaded position, re-compressing it back to
+	// its original format if it was loaded from a gzip archive (or if the
+	// -compress flag forces it), and record its checksum for future
+	// verification
 	log.Printf("unloading profile for %s\n", this.Name)
-	if err := SimpleCopy(LoadedProfile, this.Path()); err != nil {
+	srcf, err := os.Open(this.loadedProfile())
+	if err != nil {
+		return err
+	}
+	var hash string
+	if this.compressed || this.Compress {
+		hash, err = AtomicCopyCompressed(srcf, this.Path())
+	} else {
+		hash, err = AtomicCopy(srcf, this.Path())
+	}
+	srcf.Close()
+	if err != nil {
+		return err
+	}
+	if err := WriteHash(this.Path(), hash); err != nil {
 		return err
 	}
 
 	// Restore the originally loaded profile
-	return SimpleRestore(LoadedProfile)
+	return SimpleRestore(this.loadedProfile())
 }
 
 // RollBackups manages rolling backups for the profile data
@@ -144,20 +423,35 @@ func (this *Profile) RollBackups() error {
 	if err := os.RemoveAll(this.getBackup(this.Preserve - 1)); err != nil {
 		return err
 	}
+	if err := os.RemoveAll(this.getBackup(this.Preserve-1) + ".sha256"); err != nil {
+		return err
+	}
 	for i := this.Preserve - 1; i > 0; i-- {
 		if FileExists(this.getBackup(i - 1)) {
-			if err := os.Rename(this.getBackup(i-1), this.getBackup(i)); err != nil {
+			if err := renameWithHash(this.getBackup(i-1), this.getBackup(i)); err != nil {
 				return err
 			}
 		}
 	}
-	return os.Rename(this.Path(), this.getBackup(0))
+	return renameWithHash(this.Path(), this.getBackup(0))
 }
 
 func (this *Profile) getBackup(i int) string {
 	return fmt.Sprintf("%s.%d", this.Path(), i)
 }
 
+// renameWithHash renames a profile file together with its sidecar checksum,
+// if one is on record
+func renameWithHash(oldPath, newPath string) error {
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return err
+	}
+	if FileExists(oldPath + ".sha256") {
+		return os.Rename(oldPath+".sha256", newPath+".sha256")
+	}
+	return nil
+}
+
 // SimpleBackup renames the given file as a backup
 func SimpleBackup(file string) error {
 	// If the file exists, rename it as a backup
@@ -187,16 +481,148 @@ func SimpleCopy(src, dst string) error {
 	}
 	defer srcf.Close()
 
-	// Open destination file for writing
-	dstf, err := os.Create(dst)
+	return SimpleCopyReader(srcf, dst)
+}
+
+// SimpleCopyReader streams src into dst, creating/truncating dst as needed
+func SimpleCopyReader(src io.Reader, dst string) error {
+	_, err := AtomicCopy(src, dst)
+	return err
+}
+
+// AtomicCopy streams src into dst crash-safely: the data is written to a temp
+// file in dst's directory, fsynced, and moved into place with os.Rename, with
+// mirrored writes to a SHA-256 hash along the way. This avoids ever leaving a
+// half-written dst behind if the process is interrupted mid-copy. It returns
+// the hex-encoded digest of the bytes written.
+func AtomicCopy(src io.Reader, dst string) (string, error) {
+	tmpf, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	tmp := tmpf.Name()
+	defer os.Remove(tmp) // no-op once the rename below succeeds
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmpf, hash), src); err != nil {
+		tmpf.Close()
+		return "", err
+	}
+	if err := tmpf.Sync(); err != nil {
+		tmpf.Close()
+		return "", err
+	}
+	if err := tmpf.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// WriteHash persists the hex-encoded SHA-256 digest of file as a sidecar,
+// file+".sha256", for VerifyHash to check on a later run
+func WriteHash(file, hash string) error {
+	return os.WriteFile(file+".sha256", []byte(hash), 0644)
+}
+
+// VerifyHash reports whether file's contents match its sidecar checksum. A
+// missing sidecar is treated as valid, since profiles saved before checksum
+// verification was added have none.
+func VerifyHash(file string) (bool, error) {
+	want, err := os.ReadFile(file + ".sha256")
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return false, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)) == strings.TrimSpace(string(want)), nil
+}
+
+// IsGzip returns true IFF the given file begins with the gzip magic bytes
+func IsGzip(file string) (bool, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer f.Close()
+
+	head := make([]byte, len(gzipMagic))
+	if _, err := io.ReadFull(f, head); err != nil {
+		if err == io.ErrUnexpectedEOF || err == io.EOF {
+			return false, nil
+		}
+		return false, err
+	}
+	return bytes.Equal(head, gzipMagic), nil
+}
+
+// CopyDecompressed streams the gzip-compressed content of src into dst
+func CopyDecompressed(src, dst string) error {
+	srcf, err := os.Open(src)
 	if err != nil {
 		return err
 	}
-	defer dstf.Close()
+	defer srcf.Close()
 
-	// Copy source file to destination, with mirrored writes to the hash
-	_, err = io.Copy(dstf, srcf)
-	return err
+	gzr, err := gzip.NewReader(srcf)
+	if err != nil {
+		return err
+	}
+	defer gzr.Close()
+
+	return SimpleCopyReader(gzr, dst)
+}
+
+// AtomicCopyCompressed streams src into dst as a gzip archive, using the same
+// temp-file-then-rename strategy as AtomicCopy, and returns the hex-encoded
+// SHA-256 digest of the compressed bytes written to dst.
+func AtomicCopyCompressed(src io.Reader, dst string) (string, error) {
+	tmpf, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	tmp := tmpf.Name()
+	defer os.Remove(tmp) // no-op once the rename below succeeds
+
+	hash := sha256.New()
+	gzw := gzip.NewWriter(io.MultiWriter(tmpf, hash))
+	if _, err := io.Copy(gzw, src); err != nil {
+		gzw.Close()
+		tmpf.Close()
+		return "", err
+	}
+	if err := gzw.Close(); err != nil {
+		tmpf.Close()
+		return "", err
+	}
+	if err := tmpf.Sync(); err != nil {
+		tmpf.Close()
+		return "", err
+	}
+	if err := tmpf.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
 // FileExists returns true IFF the given file exists
@@ -217,4 +643,14 @@ func Exit(err error) {
 	} else {
 		os.Exit(0)
 	}
+}
+
+// ExitSet summarizes every per-instance failure from a ProfileSet launch and
+// exits the same way Exit does
+func ExitSet(failures []error) {
+	log.Printf("%d of the set's instances failed:\n", len(failures))
+	for _, err := range failures {
+		log.Println(" -", err)
+	}
+	Exit(fmt.Errorf("profile set finished with %d failed instance(s)", len(failures)))
 }
\ No newline at end of file
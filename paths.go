@@ -0,0 +1,146 @@
+/*  Copyright (C) 2017 Ryan Clarke.
+ *
+ *  Copying and distribution of this file, with or without modification,
+ *  are permitted in any medium without royalty provided the copyright
+ *  notice and this notice are preserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Paths holds the resolved Guild Wars 2 executable and profile directory
+type Paths struct {
+	Exec       string
+	ProfileDir string
+}
+
+// Config holds everything gw2util reads from its optional config file: path
+// overrides plus any named profile sets (config key "sets.<name>.members")
+type Config struct {
+	Paths Paths
+	Sets  map[string][]string
+}
+
+// ConfigPath returns the location of the optional gw2util config file,
+// os.UserConfigDir()/gw2util/config.toml
+func ConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "gw2util", "config.toml")
+}
+
+// LoadConfig reads the gw2util config file at configFile, falling back to
+// ConfigPath() when empty. A missing or unreadable config file yields a
+// zero-value Config rather than an error, since the config file is optional.
+func LoadConfig(configFile string) Config {
+	if configFile == "" {
+		configFile = ConfigPath()
+	}
+	cfg, err := parseConfigFile(configFile)
+	if err != nil {
+		return Config{Sets: map[string][]string{}}
+	}
+	return cfg
+}
+
+// ResolvePaths discovers the GW2 executable and profile directory for the
+// current OS, then applies overrides from cfg and finally execOverride.
+func ResolvePaths(cfg Config, execOverride string) Paths {
+	paths := discoverPaths()
+
+	if cfg.Paths.Exec != "" {
+		paths.Exec = cfg.Paths.Exec
+	}
+	if cfg.Paths.ProfileDir != "" {
+		paths.ProfileDir = cfg.Paths.ProfileDir
+	}
+	if execOverride != "" {
+		paths.Exec = execOverride
+	}
+	return paths
+}
+
+// parseConfigFile reads a minimal TOML-like config file: top-level
+// "key = \"value\"" pairs for "exec"/"profile_dir", and a
+// "members = [\"a\", \"b\"]" list under each "[sets.<name>]" section. This
+// is all gw2util needs without pulling in a full TOML parser.
+func parseConfigFile(file string) (Config, error) {
+	cfg := Config{Sets: map[string][]string{}}
+	f, err := os.Open(file)
+	if err != nil {
+		return cfg, err
+	}
+	defer f.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.Trim(line, "[]")
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		if strings.HasPrefix(section, "sets.") && key == "members" {
+			setName := strings.TrimPrefix(section, "sets.")
+			cfg.Sets[setName] = parseStringArray(value)
+			continue
+		}
+		if section != "" {
+			continue
+		}
+		switch key {
+		case "exec":
+			cfg.Paths.Exec = strings.Trim(value, `"`)
+		case "profile_dir":
+			cfg.Paths.ProfileDir = strings.Trim(value, `"`)
+		}
+	}
+	return cfg, scanner.Err()
+}
+
+// parseStringArray parses a TOML-style inline array of quoted strings, e.g.
+// ["main", "alt1", "alt2"]
+func parseStringArray(raw string) []string {
+	raw = strings.TrimPrefix(strings.TrimSpace(raw), "[")
+	raw = strings.TrimSuffix(raw, "]")
+
+	var members []string
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.Trim(strings.TrimSpace(part), `"`)
+		if part != "" {
+			members = append(members, part)
+		}
+	}
+	return members
+}
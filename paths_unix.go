@@ -0,0 +1,83 @@
+//go:build !windows
+
+/*  Copyright (C) 2017 Ryan Clarke.
+ *
+ *  Copying and distribution of this file, with or without modification,
+ *  are permitted in any medium without royalty provided the copyright
+ *  notice and this notice are preserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// winePrefixes lists, relative to $HOME, the common Wine/Lutris prefixes
+// searched for a Guild Wars 2 install when running outside of Windows.
+// $XDG_CONFIG_HOME itself is where gw2util's own config lives; that is
+// handled by ConfigPath via os.UserConfigDir.
+var winePrefixes = []string{
+	".wine",
+	filepath.Join(".local", "share", "lutris", "runners", "winePrefixes", "gw2"),
+}
+
+// discoverPaths probes common Wine/Lutris prefixes for a Guild Wars 2
+// install outside of Windows, falling back to the default "~/.wine" layout
+// if none is found on disk
+func discoverPaths() Paths {
+	for _, prefix := range winePrefixes {
+		driveC := filepath.Join(home(), prefix, "drive_c")
+		if execPath := findExec(driveC); execPath != "" {
+			return Paths{Exec: execPath, ProfileDir: findProfileDir(driveC)}
+		}
+	}
+
+	driveC := filepath.Join(home(), ".wine", "drive_c")
+	return Paths{
+		Exec:       filepath.Join(driveC, "Program Files (x86)", "Guild Wars 2", "Gw2-64.exe"),
+		ProfileDir: findProfileDir(driveC),
+	}
+}
+
+// findExec looks for the Guild Wars 2 executable under a Wine prefix's
+// drive_c, returning "" if it isn't installed there
+func findExec(driveC string) string {
+	path := filepath.Join(driveC, "Program Files (x86)", "Guild Wars 2", "Gw2-64.exe")
+	if FileExists(path) {
+		return path
+	}
+	return ""
+}
+
+// findProfileDir locates the Windows AppData roaming folder for Guild Wars 2
+// inside a Wine prefix's drive_c, defaulting to the "steamuser" account used
+// by most Lutris/Proton setups when no user profile is found
+func findProfileDir(driveC string) string {
+	matches, _ := filepath.Glob(filepath.Join(driveC, "users", "*", "AppData", "Roaming", "Guild Wars 2"))
+	if len(matches) > 0 {
+		return matches[0]
+	}
+	return filepath.Join(driveC, "users", "steamuser", "AppData", "Roaming", "Guild Wars 2")
+}
+
+func home() string {
+	dir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return dir
+}
@@ -0,0 +1,66 @@
+//go:build windows
+
+/*  Copyright (C) 2017 Ryan Clarke.
+ *
+ *  Copying and distribution of this file, with or without modification,
+ *  are permitted in any medium without royalty provided the copyright
+ *  notice and this notice are preserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// uninstallKey is where the Guild Wars 2 installer records its install
+// location
+const uninstallKey = `HKLM\SOFTWARE\WOW6432Node\Microsoft\Windows\CurrentVersion\Uninstall\Guild Wars 2`
+
+// discoverPaths probes %APPDATA% for the profile directory and the registry
+// uninstall keys for the Guild Wars 2 executable
+func discoverPaths() Paths {
+	execPath := `C:\Program Files (x86)\Guild Wars 2\Gw2-64.exe`
+	if found := execFromRegistry(); found != "" {
+		execPath = found
+	}
+	return Paths{
+		Exec:       execPath,
+		ProfileDir: filepath.Join(os.Getenv("APPDATA"), "Guild Wars 2"),
+	}
+}
+
+// execFromRegistry looks up the InstallLocation recorded under the Guild
+// Wars 2 uninstall registry key
+func execFromRegistry() string {
+	out, err := exec.Command("reg", "query", uninstallKey, "/v", "InstallLocation").Output()
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		i := strings.Index(line, "REG_SZ")
+		if i < 0 {
+			continue
+		}
+		dir := strings.TrimSpace(line[i+len("REG_SZ"):])
+		if dir != "" {
+			return filepath.Join(dir, "Gw2-64.exe")
+		}
+	}
+	return ""
+}
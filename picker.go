@@ -0,0 +1,266 @@
+/*  Copyright (C) 2017 Ryan Clarke.
+ *
+ *  Copying and distribution of this file, with or without modification,
+ *  are permitted in any medium without royalty provided the copyright
+ *  notice and this notice are preserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// profileEntry describes a saved profile discovered in ProfileDir
+type profileEntry struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+// Key codes returned by readKey for control keys that aren't literal
+// characters to add to the filter
+const (
+	keyUp = -(iota + 1)
+	keyDown
+	keyEnter
+	keyBackspace
+	keyCancel
+)
+
+// PickProfile prompts the user to choose from the profiles saved in
+// ProfileDir, most recently modified first, and returns the chosen name.
+// "Local (no swap)" is always offered first. Returns "Local" unchanged if no
+// other profiles are found. Where the terminal supports raw mode (currently
+// Linux), this is an arrow-key, filter-as-you-type menu; elsewhere it falls
+// back to a plain numbered prompt.
+func PickProfile() (string, error) {
+	entries, err := discoverProfiles()
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 0 {
+		return "Local", nil
+	}
+
+	if restore, err := enableRawMode(); err == nil {
+		defer restore()
+		return pickInteractive(entries)
+	}
+	return pickNumbered(entries)
+}
+
+// pickNumbered is the plain-terminal fallback: print every candidate with a
+// number and read a line with the chosen index
+func pickNumbered(entries []profileEntry) (string, error) {
+	fmt.Println("select a profile to launch:")
+	fmt.Println("  0) Local (no swap)")
+	for i, e := range entries {
+		fmt.Printf("  %d) %-30s %10d bytes  %s\n", i+1, e.name, e.size, e.modTime.Format("2006-01-02 15:04"))
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Print("> ")
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		choice, err := strconv.Atoi(strings.TrimSpace(line))
+		if err != nil || choice < 0 || choice > len(entries) {
+			fmt.Println("invalid selection, try again")
+			continue
+		}
+		if choice == 0 {
+			return "Local", nil
+		}
+		return entries[choice-1].name, nil
+	}
+}
+
+// pickInteractive drives an arrow-key, filter-as-you-type menu over a raw
+// terminal: typed characters narrow the candidate list, up/down move the
+// selection, enter confirms, and esc/ctrl-c cancel back to "Local".
+func pickInteractive(entries []profileEntry) (string, error) {
+	all := append([]profileEntry{{name: "Local"}}, entries...)
+	filter := ""
+	selected := 0
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Println("select a profile (type to filter, ↑/↓ to move, enter to confirm, esc to cancel):")
+
+	linesDrawn := 0
+	finish := func(name string, err error) (string, error) {
+		if linesDrawn > 0 {
+			fmt.Print("\n")
+		}
+		return name, err
+	}
+
+	for {
+		filtered := filterEntries(all, filter)
+		if selected >= len(filtered) {
+			selected = len(filtered) - 1
+		}
+		if selected < 0 {
+			selected = 0
+		}
+
+		if linesDrawn > 0 {
+			fmt.Printf("\x1b[%dA\x1b[J", linesDrawn)
+		}
+		linesDrawn = drawMenu(filtered, filter, selected)
+
+		key, err := readKey(reader)
+		if err != nil {
+			return finish("", err)
+		}
+		switch key {
+		case keyUp:
+			if selected > 0 {
+				selected--
+			}
+		case keyDown:
+			if selected < len(filtered)-1 {
+				selected++
+			}
+		case keyEnter:
+			if len(filtered) == 0 {
+				return finish("Local", nil)
+			}
+			return finish(filtered[selected].name, nil)
+		case keyBackspace:
+			if len(filter) > 0 {
+				filter = filter[:len(filter)-1]
+			}
+		case keyCancel:
+			return finish("Local", nil)
+		default:
+			if key >= 0x20 && key < 0x7f {
+				filter += string(rune(key))
+			}
+		}
+	}
+}
+
+// drawMenu prints the filter line and each candidate, marking the selected
+// row, and returns how many lines were written so the next frame can erase
+// exactly that much before redrawing
+func drawMenu(entries []profileEntry, filter string, selected int) int {
+	fmt.Printf("filter: %s\n", filter)
+	for i, e := range entries {
+		marker := "  "
+		if i == selected {
+			marker = "> "
+		}
+		if e.name == "Local" {
+			fmt.Printf("%sLocal (no swap)\n", marker)
+		} else {
+			fmt.Printf("%s%-30s %10d bytes  %s\n", marker, e.name, e.size, e.modTime.Format("2006-01-02 15:04"))
+		}
+	}
+	return len(entries) + 1
+}
+
+// filterEntries returns the entries whose name contains filter, case
+// insensitively, preserving order
+func filterEntries(entries []profileEntry, filter string) []profileEntry {
+	if filter == "" {
+		return entries
+	}
+	filtered := make([]profileEntry, 0, len(entries))
+	for _, e := range entries {
+		if strings.Contains(strings.ToLower(e.name), strings.ToLower(filter)) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+// readKey reads a single keypress from a raw terminal, translating the
+// escape sequences for the arrow keys into the keyUp/keyDown sentinels
+func readKey(r *bufio.Reader) (int, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	switch b {
+	case '\r', '\n':
+		return keyEnter, nil
+	case 0x7f, 0x08:
+		return keyBackspace, nil
+	case 0x03:
+		return keyCancel, nil
+	case 0x1b:
+		b2, err := r.ReadByte()
+		if err != nil || b2 != '[' {
+			return keyCancel, nil
+		}
+		b3, err := r.ReadByte()
+		if err != nil {
+			return keyCancel, nil
+		}
+		switch b3 {
+		case 'A':
+			return keyUp, nil
+		case 'B':
+			return keyDown, nil
+		default:
+			return 0, nil
+		}
+	default:
+		return int(b), nil
+	}
+}
+
+// discoverProfiles scans ProfileDir for saved "*.dat" profiles, most recently
+// modified first
+func discoverProfiles() ([]profileEntry, error) {
+	matches, err := filepath.Glob(filepath.Join(ProfileDir, "*.dat"))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]profileEntry, 0, len(matches))
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			continue
+		}
+		name := strings.TrimSuffix(filepath.Base(match), ".dat")
+		if strings.EqualFold(name, "local") {
+			continue
+		}
+		entries = append(entries, profileEntry{name: name, size: info.Size(), modTime: info.ModTime()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].modTime.After(entries[j].modTime) })
+	return entries, nil
+}
+
+// isInteractive reports whether stdin looks like a terminal rather than a
+// pipe or redirected file
+func isInteractive() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,123 @@
+/*  Copyright (C) 2017 Ryan Clarke.
+ *
+ *  Copying and distribution of this file, with or without modification,
+ *  are permitted in any medium without royalty provided the copyright
+ *  notice and this notice are preserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ProfileSet launches a named group of profiles concurrently, each as its
+// own isolated Guild Wars 2 instance sharing one client install.
+type ProfileSet struct {
+	Name         string
+	Members      []string
+	Preserve     int
+	Options      []string
+	Insecure     bool
+	FetchTimeout time.Duration
+	Compress     bool
+}
+
+// instanceResult carries the outcome of running one set member through to
+// completion
+type instanceResult struct {
+	Member string
+	Err    error
+}
+
+// Launch loads every member's profile, runs them concurrently, and rolls
+// each one's backups back in on exit, regardless of how the others fared.
+// It returns one error per member that failed to load, launch, or unload.
+func (this *ProfileSet) Launch() []error {
+	var failures []error
+	var runnable []*Profile
+	for _, profile := range this.profiles() {
+		if err := profile.LoadFile(); err != nil {
+			failures = append(failures, fmt.Errorf("%s: loading profile: %w", profile.Name, err))
+			continue
+		}
+		runnable = append(runnable, profile)
+	}
+
+	results := make(chan instanceResult, len(runnable))
+	var wg sync.WaitGroup
+	for _, profile := range runnable {
+		wg.Add(1)
+		go func(profile *Profile) {
+			defer wg.Done()
+			results <- instanceResult{Member: profile.Name, Err: launchInstance(profile)}
+		}(profile)
+	}
+	wg.Wait()
+	close(results)
+
+	for result := range results {
+		if result.Err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", result.Member, result.Err))
+		}
+	}
+	return failures
+}
+
+// launchInstance runs a single member's Guild Wars 2 instance to completion
+// and unloads its profile, regardless of whether the launch itself succeeded
+func launchInstance(profile *Profile) error {
+	log.Printf("launching Guild Wars 2 for %s\n", profile.Name)
+	runErr := exec.Command(ExecPath, profile.Options...).Run()
+	time.Sleep(1 * time.Second)
+
+	if err := profile.UnloadFile(); err != nil {
+		if runErr != nil {
+			return fmt.Errorf("launch failed (%v) and unload failed: %w", runErr, err)
+		}
+		return err
+	}
+	return runErr
+}
+
+// profiles builds one isolated Profile per member. Each gets its own
+// LoadedProfile under ProfileDir/sets/<set>/<member>/Local.dat, and that same
+// instanceDir is passed to the client via -userdir so the running GW2
+// instance actually reads and writes its AppData there instead of the real
+// profile directory; without that flag concurrent instances would still
+// contend for the one real Local.dat. -shareArchive lets them share the one
+// installed Gw2.dat game archive.
+func (this *ProfileSet) profiles() []*Profile {
+	profiles := make([]*Profile, len(this.Members))
+	for i, member := range this.Members {
+		instanceDir := filepath.Join(ProfileDir, "sets", this.Name, member)
+		options := append(append([]string{}, this.Options...), "-shareArchive", "-userdir", instanceDir)
+		profiles[i] = &Profile{
+			Name:          member,
+			Preserve:      this.Preserve,
+			Options:       options,
+			Insecure:      this.Insecure,
+			FetchTimeout:  this.FetchTimeout,
+			Compress:      this.Compress,
+			LoadedProfile: filepath.Join(instanceDir, "Local.dat"),
+		}
+	}
+	return profiles
+}
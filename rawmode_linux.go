@@ -0,0 +1,82 @@
+//go:build linux
+
+/*  Copyright (C) 2017 Ryan Clarke.
+ *
+ *  Copying and distribution of this file, with or without modification,
+ *  are permitted in any medium without royalty provided the copyright
+ *  notice and this notice are preserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// Linux termios ioctl requests, and the c_lflag/c_cc layout needed to turn
+// off canonical line editing, echo and signal keys (see
+// asm-generic/termbits.h). This
+// talks to the kernel's TCGETS/TCSETS directly rather than pulling in a
+// terminal library, matching the rest of gw2util's no-dependencies approach.
+const (
+	tcgets = 0x5401
+	tcsets = 0x5402
+
+	lflagISIG   = 0x0001
+	lflagICANON = 0x0002
+	lflagECHO   = 0x0008
+
+	ccVTIME = 5
+	ccVMIN  = 6
+)
+
+type termios struct {
+	Iflag, Oflag, Cflag, Lflag uint32
+	Line                       byte
+	Cc                         [19]byte
+}
+
+// enableRawMode switches stdin to raw mode (no canonical line buffering, no
+// local echo, no signal keys) so PickProfile can read arrow keys and ctrl-c a
+// byte at a time, and returns a function that restores the previous terminal
+// state.
+func enableRawMode() (func(), error) {
+	fd := os.Stdin.Fd()
+
+	var oldState termios
+	if err := termiosIoctl(fd, tcgets, &oldState); err != nil {
+		return nil, err
+	}
+
+	newState := oldState
+	newState.Lflag &^= lflagISIG | lflagICANON | lflagECHO
+	newState.Cc[ccVMIN] = 1
+	newState.Cc[ccVTIME] = 0
+	if err := termiosIoctl(fd, tcsets, &newState); err != nil {
+		return nil, err
+	}
+
+	return func() { termiosIoctl(fd, tcsets, &oldState) }, nil
+}
+
+func termiosIoctl(fd uintptr, request uintptr, state *termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, uintptr(unsafe.Pointer(state)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
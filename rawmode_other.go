@@ -0,0 +1,33 @@
+//go:build !linux
+
+/*  Copyright (C) 2017 Ryan Clarke.
+ *
+ *  Copying and distribution of this file, with or without modification,
+ *  are permitted in any medium without royalty provided the copyright
+ *  notice and this notice are preserved.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package main
+
+import "errors"
+
+// errRawModeUnsupported is returned by enableRawMode wherever gw2util has no
+// dependency-free way to flip a terminal into raw mode, so PickProfile falls
+// back to its plain numbered prompt instead.
+var errRawModeUnsupported = errors.New("raw terminal mode not supported on this platform")
+
+func enableRawMode() (func(), error) {
+	return nil, errRawModeUnsupported
+}